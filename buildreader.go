@@ -0,0 +1,168 @@
+package merkletree
+
+import (
+	"fmt"
+	"io"
+)
+
+// BuildReader builds a Tree by reading fixed-size segments from r instead
+// of requiring the caller to first load every block into a [][]byte. Each
+// segment (the last one may be shorter) becomes one leaf. This is the
+// streaming counterpart to NewTree for Merkle-hashing large files without
+// holding all of their raw bytes in memory at once.
+func BuildReader(r io.Reader, segmentSize int64, checksumFunc ChecksumFunc) (*Tree, error) {
+	if segmentSize <= 0 {
+		return nil, fmt.Errorf("merkletree: segmentSize must be positive")
+	}
+
+	var leaves []*Node
+	buf := make([]byte, segmentSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			segment := append([]byte{}, buf[:n]...)
+			leaves = append(leaves, &Node{checksum: checksumFunc(true, segment)})
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(leaves) == 1 {
+		leaves = append(leaves, leaves[0])
+	}
+
+	t := &Tree{checksumFunc: checksumFunc, rows: [][]*Node{leaves}}
+	t.build()
+
+	return t, nil
+}
+
+// stackEntry is one level of BuildReaderProof's merge stack: a complete
+// subtree root of the given height along with its checksum.
+type stackEntry struct {
+	height   uint64
+	checksum []byte
+}
+
+// BuildReaderProof streams segments from r and returns the tree's root
+// and an audit proof for the leaf at proofIndex, without ever holding
+// more than O(log n) intermediate hashes live. It reads r exactly once.
+//
+// Internally it maintains a stack of complete subtree hashes, merging the
+// top two as soon as they share a height (mirroring Bitcoin-style
+// incremental Merkle root computation), and pads any odd subtree left at
+// the end by combining it with itself, one height at a time, matching
+// NewTree's duplicate-to-balance behavior exactly. While the leaf at
+// proofIndex is live on the stack, every merge or pad that touches it is
+// recorded as a proof step.
+func BuildReaderProof(r io.Reader, segmentSize int64, checksumFunc ChecksumFunc, proofIndex uint64) (root []byte, proof *Proof, numLeaves uint64, err error) {
+	if segmentSize <= 0 {
+		return nil, nil, 0, fmt.Errorf("merkletree: segmentSize must be positive")
+	}
+
+	var stack []stackEntry
+	var parts []*ProofPart
+	var target []byte
+
+	// ancestorDepth is the distance from the top of the stack to the
+	// subtree currently containing the proved leaf, or -1 if that leaf
+	// hasn't been read yet.
+	ancestorDepth := -1
+
+	push := func(e stackEntry) {
+		stack = append(stack, e)
+		if ancestorDepth >= 0 {
+			ancestorDepth++
+		}
+	}
+
+	mergeTop := func() {
+		b := stack[len(stack)-1]
+		a := stack[len(stack)-2]
+		stack = stack[:len(stack)-2]
+
+		switch ancestorDepth {
+		case 0:
+			parts = append(parts, &ProofPart{isRight: false, checksum: a.checksum})
+			ancestorDepth = 0
+		case 1:
+			parts = append(parts, &ProofPart{isRight: true, checksum: b.checksum})
+			ancestorDepth = 0
+		default:
+			if ancestorDepth > 1 {
+				ancestorDepth--
+			}
+		}
+
+		stack = append(stack, stackEntry{
+			height:   a.height + 1,
+			checksum: checksumFunc(false, concat(a.checksum, b.checksum)),
+		})
+	}
+
+	var count uint64
+	buf := make([]byte, segmentSize)
+	for {
+		n, rerr := io.ReadFull(r, buf)
+		if n > 0 {
+			leafChecksum := checksumFunc(true, append([]byte{}, buf[:n]...))
+
+			push(stackEntry{height: 0, checksum: leafChecksum})
+			if count == proofIndex {
+				target = leafChecksum
+				ancestorDepth = 0
+			}
+			count++
+
+			for len(stack) >= 2 && stack[len(stack)-1].height == stack[len(stack)-2].height {
+				mergeTop()
+			}
+		}
+		if rerr == io.EOF || rerr == io.ErrUnexpectedEOF {
+			break
+		}
+		if rerr != nil {
+			return nil, nil, 0, rerr
+		}
+	}
+
+	if proofIndex >= count {
+		return nil, nil, count, fmt.Errorf("merkletree: proofIndex %d out of range for %d leaves", proofIndex, count)
+	}
+
+	if count == 1 {
+		self := stack[0].checksum
+		root := checksumFunc(false, concat(self, self))
+		proof := &Proof{
+			parts:        []*ProofPart{{isRight: true, checksum: self}},
+			target:       self,
+			checksumFunc: checksumFunc,
+		}
+		return root, proof, 1, nil
+	}
+
+	// Heights strictly decrease from the bottom to the top of the stack,
+	// so the top entry is always the one left over; pad it up to the
+	// next height by combining it with itself until it can merge.
+	for len(stack) > 1 {
+		top := len(stack) - 1
+		if stack[top].height == stack[top-1].height {
+			mergeTop()
+			continue
+		}
+
+		if ancestorDepth == 0 {
+			parts = append(parts, &ProofPart{isRight: true, checksum: stack[top].checksum})
+		}
+		self := stack[top].checksum
+		stack[top] = stackEntry{
+			height:   stack[top].height + 1,
+			checksum: checksumFunc(false, concat(self, self)),
+		}
+	}
+
+	return stack[0].checksum, &Proof{parts: parts, target: target, checksumFunc: checksumFunc}, count, nil
+}
@@ -0,0 +1,78 @@
+package merkletree
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBuildReader(t *testing.T) {
+	words := []string{"alpha", "kappa", "gamma", "zetaa", "omega", "epsil", "aaaaa", "bbbbb", "ccccc"}
+
+	for n := 1; n <= len(words); n++ {
+		blocks := make([][]byte, n)
+		var buf bytes.Buffer
+		for i := 0; i < n; i++ {
+			blocks[i] = []byte(words[i])
+			buf.WriteString(words[i])
+		}
+
+		want := NewTree(IdentityHashForTest, blocks)
+		got, err := BuildReader(&buf, int64(len("alpha")), IdentityHashForTest)
+		if err != nil {
+			t.Fatalf("n=%d: BuildReader: %v", n, err)
+		}
+
+		if !bytes.Equal(got.root.GetChecksum(), want.root.GetChecksum()) {
+			t.Fatalf("n=%d: got root %q, want %q", n, got.root.GetChecksum(), want.root.GetChecksum())
+		}
+	}
+}
+
+func TestBuildReaderProof(t *testing.T) {
+	words := []string{"alpha", "kappa", "gamma", "zetaa", "omega", "epsil", "aaaaa", "bbbbb", "ccccc"}
+
+	for n := 1; n <= len(words); n++ {
+		blocks := make([][]byte, n)
+		for i := 0; i < n; i++ {
+			blocks[i] = []byte(words[i])
+		}
+		want := NewTree(Sha256DoubleHash, blocks)
+
+		for target := 0; target < n; target++ {
+			var buf strings.Builder
+			for i := 0; i < n; i++ {
+				buf.WriteString(words[i])
+			}
+
+			root, proof, numLeaves, err := BuildReaderProof(strings.NewReader(buf.String()), int64(len("alpha")), Sha256DoubleHash, uint64(target))
+			if err != nil {
+				t.Fatalf("n=%d target=%d: BuildReaderProof: %v", n, target, err)
+			}
+			if numLeaves != uint64(n) {
+				t.Fatalf("n=%d target=%d: got numLeaves=%d, want %d", n, target, numLeaves, n)
+			}
+			if !bytes.Equal(root, want.root.GetChecksum()) {
+				t.Fatalf("n=%d target=%d: root mismatch", n, target)
+			}
+
+			wantProof, err := want.CreateProof(want.checksumFunc(true, blocks[target]))
+			if err != nil {
+				t.Fatalf("n=%d target=%d: CreateProof: %v", n, target, err)
+			}
+			if !wantProof.Equals(proof) {
+				t.Fatalf("n=%d target=%d: proof mismatch", n, target)
+			}
+			if !want.VerifyProof(proof) {
+				t.Fatalf("n=%d target=%d: VerifyProof = false", n, target)
+			}
+		}
+	}
+
+	t.Run("proofIndex out of range", func(t *testing.T) {
+		_, _, _, err := BuildReaderProof(strings.NewReader("alphabeta"), 5, Sha256DoubleHash, 5)
+		if err == nil {
+			t.Fail()
+		}
+	})
+}
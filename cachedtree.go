@@ -0,0 +1,105 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// CachedTree builds a Tree over a small number of pre-computed subtree
+// roots rather than raw blocks, so that appending new data to a large
+// dataset only costs hashing the new subtree plus recombining the
+// (typically few) existing subtree roots — the untouched portions of
+// the dataset are never rehashed. Each pushed subtree's height and root
+// are bound together into its leaf checksum, so a proof for a subtree at
+// one height can't be replayed as a proof for the same root at another
+// height.
+type CachedTree struct {
+	checksumFunc ChecksumFunc
+	heights      []uint64
+	roots        [][]byte
+	tree         *Tree
+}
+
+// NewCachedTree creates an empty CachedTree using hash to combine
+// subtree roots into the outer tree.
+func NewCachedTree(hash ChecksumFunc) *CachedTree {
+	return &CachedTree{checksumFunc: hash}
+}
+
+// PushSubTree inserts root — the already-computed root of a subtree
+// covering 2^height real leaves — as the next leaf of the outer tree,
+// without rehashing anything belonging to that subtree.
+func (ct *CachedTree) PushSubTree(height uint64, root []byte) {
+	ct.heights = append(ct.heights, height)
+	ct.roots = append(ct.roots, root)
+	ct.rebuild()
+}
+
+// NumSubTrees returns the number of subtree roots pushed so far.
+func (ct *CachedTree) NumSubTrees() int {
+	return len(ct.roots)
+}
+
+// Root returns the outer tree's root checksum, or nil if no subtree has
+// been pushed yet.
+func (ct *CachedTree) Root() []byte {
+	if ct.tree == nil {
+		return nil
+	}
+	return ct.tree.root.GetChecksum()
+}
+
+// rebuild recombines the outer tree from ct.roots. Only the O(number of
+// subtrees) combining work above the cached roots is redone; none of the
+// hashing that produced those roots is repeated.
+func (ct *CachedTree) rebuild() {
+	leaves := make([]*Node, len(ct.roots))
+	for i := range ct.roots {
+		leaves[i] = &Node{checksum: subTreeLeaf(ct.heights[i], ct.roots[i])}
+	}
+	if len(leaves) == 1 {
+		leaves = append(leaves, leaves[0])
+	}
+
+	t := &Tree{checksumFunc: ct.checksumFunc, rows: [][]*Node{leaves}}
+	t.build()
+	ct.tree = t
+}
+
+// subTreeLeaf binds height and root together into the checksum the
+// outer tree treats as a leaf, domain-separating subtree roots by
+// height.
+func subTreeLeaf(height uint64, root []byte) []byte {
+	buf := make([]byte, 8, 8+len(root))
+	binary.BigEndian.PutUint64(buf, height)
+	return append(buf, root...)
+}
+
+// Prove builds an audit proof that the subtree root pushed at
+// subtreeIndex (numbered in push order) is included in the outer tree.
+// The proof is built from subtreeIndex's position in the outer tree, not
+// by looking up its checksum, so two subtrees pushed with the same
+// (height, root) — and therefore the same leaf checksum — each get their
+// own proof rather than one shadowing the other.
+func (ct *CachedTree) Prove(subtreeIndex uint64) (*Proof, error) {
+	if ct.tree == nil || subtreeIndex >= uint64(len(ct.roots)) {
+		return nil, fmt.Errorf("merkletree: subtreeIndex %d out of range for %d cached subtrees", subtreeIndex, len(ct.roots))
+	}
+
+	return ct.tree.proofAt(int(subtreeIndex)), nil
+}
+
+// VerifySubTreeProof reports whether proof demonstrates that the
+// subtree with the given height and root is included in the tree whose
+// root checksum is ct.Root().
+func (ct *CachedTree) VerifySubTreeProof(height uint64, root []byte, proof *Proof) bool {
+	if ct.tree == nil {
+		return false
+	}
+	if !bytes.Equal(proof.target, subTreeLeaf(height, root)) {
+		return false
+	}
+
+	return ct.tree.VerifyProof(proof)
+}
@@ -0,0 +1,122 @@
+package merkletree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func subTreeRoot(blocks [][]byte) []byte {
+	return NewTree(Sha256DoubleHash, blocks).root.GetChecksum()
+}
+
+func TestCachedTreePushAndProve(t *testing.T) {
+	subTrees := [][][]byte{
+		{[]byte("alpha"), []byte("beta")},
+		{[]byte("kappa"), []byte("gamma")},
+		{[]byte("epsilon"), []byte("omega")},
+	}
+
+	ct := NewCachedTree(Sha256DoubleHash)
+	for _, blocks := range subTrees {
+		ct.PushSubTree(1, subTreeRoot(blocks))
+	}
+
+	if ct.NumSubTrees() != len(subTrees) {
+		t.Fatalf("got %d subtrees, want %d", ct.NumSubTrees(), len(subTrees))
+	}
+
+	for i := range subTrees {
+		proof, err := ct.Prove(uint64(i))
+		if err != nil {
+			t.Fatalf("Prove(%d): %v", i, err)
+		}
+
+		if !ct.VerifySubTreeProof(1, subTreeRoot(subTrees[i]), proof) {
+			t.Fatalf("VerifySubTreeProof(%d) = false", i)
+		}
+	}
+}
+
+func TestCachedTreeIncrementalPushMatchesFreshBuild(t *testing.T) {
+	root1 := subTreeRoot([][]byte{[]byte("alpha"), []byte("beta")})
+	root2 := subTreeRoot([][]byte{[]byte("kappa"), []byte("gamma")})
+
+	incremental := NewCachedTree(Sha256DoubleHash)
+	incremental.PushSubTree(1, root1)
+
+	fresh := NewCachedTree(Sha256DoubleHash)
+	fresh.PushSubTree(1, root1)
+	fresh.PushSubTree(1, root2)
+
+	incremental.PushSubTree(1, root2)
+
+	if string(incremental.Root()) != string(fresh.Root()) {
+		t.Fatal("incrementally pushed tree's root differs from a tree built with the same pushes at once")
+	}
+}
+
+func TestCachedTreeRejectsWrongHeight(t *testing.T) {
+	root := subTreeRoot([][]byte{[]byte("alpha"), []byte("beta")})
+
+	ct := NewCachedTree(Sha256DoubleHash)
+	ct.PushSubTree(1, root)
+	ct.PushSubTree(1, subTreeRoot([][]byte{[]byte("kappa"), []byte("gamma")}))
+
+	proof, err := ct.Prove(0)
+	if err != nil {
+		t.Fatalf("Prove: %v", err)
+	}
+
+	if ct.VerifySubTreeProof(2, root, proof) {
+		t.Fatal("VerifySubTreeProof accepted the right root at the wrong height")
+	}
+}
+
+func TestCachedTreeProveDuplicateRoots(t *testing.T) {
+	rootA := subTreeRoot([][]byte{[]byte("alpha"), []byte("beta")})
+	rootB := subTreeRoot([][]byte{[]byte("kappa"), []byte("gamma")})
+
+	ct := NewCachedTree(Sha256DoubleHash)
+	ct.PushSubTree(1, rootA)
+	ct.PushSubTree(1, rootB)
+	ct.PushSubTree(1, rootA)
+
+	proof0, err := ct.Prove(0)
+	if err != nil {
+		t.Fatalf("Prove(0): %v", err)
+	}
+	proof2, err := ct.Prove(2)
+	if err != nil {
+		t.Fatalf("Prove(2): %v", err)
+	}
+
+	if !ct.VerifySubTreeProof(1, rootA, proof0) {
+		t.Fatal("VerifySubTreeProof(0) = false")
+	}
+	if !ct.VerifySubTreeProof(1, rootA, proof2) {
+		t.Fatal("VerifySubTreeProof(2) = false")
+	}
+
+	if len(proof0.parts) == len(proof2.parts) {
+		allEqual := true
+		for i := range proof0.parts {
+			if proof0.parts[i].isRight != proof2.parts[i].isRight ||
+				!bytes.Equal(proof0.parts[i].checksum, proof2.parts[i].checksum) {
+				allEqual = false
+				break
+			}
+		}
+		if allEqual {
+			t.Fatal("Prove(0) and Prove(2) returned the same path for two different indices sharing a root")
+		}
+	}
+}
+
+func TestCachedTreeProveOutOfRange(t *testing.T) {
+	ct := NewCachedTree(Sha256DoubleHash)
+	ct.PushSubTree(1, subTreeRoot([][]byte{[]byte("alpha"), []byte("beta")}))
+
+	if _, err := ct.Prove(1); err == nil {
+		t.Fatal("Prove: want error for an out-of-range subtreeIndex")
+	}
+}
@@ -0,0 +1,213 @@
+package merkletree
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// CTTree is a Merkle tree that follows the RFC 6962 Certificate
+// Transparency Merkle Tree Hash rules: MTH({d0}) = H(0x00 || d0), and for
+// n>1, MTH(D[n]) = H(0x01 || MTH(D[0:k]) || MTH(D[k:n])) where k is the
+// largest power of two strictly smaller than n. Unlike Tree, levels are
+// never balanced by duplicating a node, so CTTree is suitable for
+// append-only, auditable logs where the shape of the tree must not change
+// when new leaves are appended.
+type CTTree struct {
+	checksumFunc ChecksumFunc
+	leafHashes   [][]byte
+	root         []byte
+}
+
+// NewCTTree builds a CTTree over blocks using hash.
+func NewCTTree(hash ChecksumFunc, blocks [][]byte) *CTTree {
+	leafHashes := make([][]byte, len(blocks))
+	for i, block := range blocks {
+		leafHashes[i] = hash(true, block)
+	}
+
+	t := &CTTree{checksumFunc: hash, leafHashes: leafHashes}
+	if len(leafHashes) > 0 {
+		t.root = mth(hash, leafHashes)
+	}
+
+	return t
+}
+
+// Root returns the tree's Merkle Tree Hash.
+func (t *CTTree) Root() []byte {
+	return t.root
+}
+
+// mth computes the RFC 6962 Merkle Tree Hash of leafHashes.
+func mth(hash ChecksumFunc, leafHashes [][]byte) []byte {
+	if len(leafHashes) == 1 {
+		return leafHashes[0]
+	}
+
+	k := largestPowerOfTwoBelow(uint64(len(leafHashes)))
+	left := mth(hash, leafHashes[:k])
+	right := mth(hash, leafHashes[k:])
+
+	return hash(false, concat(left, right))
+}
+
+// largestPowerOfTwoBelow returns the largest power of two strictly
+// smaller than n, for n>1.
+func largestPowerOfTwoBelow(n uint64) uint64 {
+	k := uint64(1)
+	for k*2 < n {
+		k *= 2
+	}
+	return k
+}
+
+// CreateProof builds an audit proof that the leaf at index is included in
+// the tree.
+func (t *CTTree) CreateProof(index uint64) (*Proof, error) {
+	n := uint64(len(t.leafHashes))
+	if n == 0 || index >= n {
+		return nil, fmt.Errorf("merkletree: index %d out of range for tree of size %d", index, n)
+	}
+
+	parts := path(t.checksumFunc, index, t.leafHashes)
+
+	return &Proof{parts: parts, target: t.leafHashes[index], checksumFunc: t.checksumFunc}, nil
+}
+
+// path implements the RFC 6962 PATH(m, D[n]) audit path recurrence,
+// returning sibling hashes ordered from the leaf up to the root.
+func path(hash ChecksumFunc, m uint64, leafHashes [][]byte) []*ProofPart {
+	n := uint64(len(leafHashes))
+	if n == 1 {
+		return nil
+	}
+
+	k := largestPowerOfTwoBelow(n)
+	if m < k {
+		parts := path(hash, m, leafHashes[:k])
+		return append(parts, &ProofPart{isRight: true, checksum: mth(hash, leafHashes[k:])})
+	}
+
+	parts := path(hash, m-k, leafHashes[k:])
+	return append(parts, &ProofPart{isRight: false, checksum: mth(hash, leafHashes[:k])})
+}
+
+// VerifyProof reports whether proof reconstructs the tree's root hash.
+func (t *CTTree) VerifyProof(proof *Proof) bool {
+	if t.root == nil {
+		return false
+	}
+
+	current := proof.target
+	for _, part := range proof.parts {
+		if part.isRight {
+			current = t.checksumFunc(false, concat(current, part.checksum))
+		} else {
+			current = t.checksumFunc(false, concat(part.checksum, current))
+		}
+	}
+
+	return bytes.Equal(current, t.root)
+}
+
+// CreateConsistencyProof builds a proof that the tree of size newSize is
+// an append-only extension of an earlier tree of size oldSize, using the
+// RFC 6962 SUBPROOF(m, D[n], true) recurrence.
+func (t *CTTree) CreateConsistencyProof(oldSize, newSize uint64) ([][]byte, error) {
+	if oldSize > newSize || newSize > uint64(len(t.leafHashes)) {
+		return nil, fmt.Errorf("merkletree: invalid sizes %d, %d for tree of size %d", oldSize, newSize, len(t.leafHashes))
+	}
+	if oldSize == 0 || oldSize == newSize {
+		return nil, nil
+	}
+
+	return subProof(t.checksumFunc, oldSize, t.leafHashes[:newSize], true), nil
+}
+
+// subProof implements the RFC 6962 SUBPROOF(m, D[n], b) recurrence,
+// returning the complete-subtree hashes needed to reconstruct both the
+// old root (size m) and the new root (size n) from the old root onward.
+func subProof(hash ChecksumFunc, m uint64, leafHashes [][]byte, b bool) [][]byte {
+	n := uint64(len(leafHashes))
+	if m == n {
+		if b {
+			return nil
+		}
+		return [][]byte{mth(hash, leafHashes)}
+	}
+
+	k := largestPowerOfTwoBelow(n)
+	if m <= k {
+		proof := subProof(hash, m, leafHashes[:k], b)
+		return append(proof, mth(hash, leafHashes[k:]))
+	}
+
+	proof := subProof(hash, m-k, leafHashes[k:], false)
+	return append(proof, mth(hash, leafHashes[:k]))
+}
+
+// VerifyConsistencyProof reports whether proof demonstrates that a tree
+// with root newRoot and newSize leaves is an append-only extension of a
+// tree with root oldRoot and oldSize leaves.
+func VerifyConsistencyProof(hash ChecksumFunc, oldRoot, newRoot []byte, oldSize, newSize uint64, proof [][]byte) bool {
+	if oldSize > newSize {
+		return false
+	}
+	if oldSize == 0 {
+		return true
+	}
+	if oldSize == newSize {
+		return len(proof) == 0 && bytes.Equal(oldRoot, newRoot)
+	}
+
+	idx := 0
+	oldHash, newHash, ok := verifySubProof(hash, proof, &idx, oldSize, newSize, true, oldRoot)
+	if !ok || idx != len(proof) {
+		return false
+	}
+
+	return bytes.Equal(oldHash, oldRoot) && bytes.Equal(newHash, newRoot)
+}
+
+// verifySubProof mirrors subProof's recursion, consuming proof hashes in
+// the same order they were emitted and reconstructing both the old and
+// new hash for the subtree of size n currently under consideration.
+func verifySubProof(hash ChecksumFunc, proof [][]byte, idx *int, m, n uint64, b bool, oldRoot []byte) (oldHash, newHash []byte, ok bool) {
+	if m == n {
+		if b {
+			return oldRoot, oldRoot, true
+		}
+		if *idx >= len(proof) {
+			return nil, nil, false
+		}
+		h := proof[*idx]
+		*idx++
+		return h, h, true
+	}
+
+	k := largestPowerOfTwoBelow(n)
+	if m <= k {
+		oldLeft, newLeft, ok := verifySubProof(hash, proof, idx, m, k, b, oldRoot)
+		if !ok {
+			return nil, nil, false
+		}
+		if *idx >= len(proof) {
+			return nil, nil, false
+		}
+		right := proof[*idx]
+		*idx++
+		return oldLeft, hash(false, concat(newLeft, right)), true
+	}
+
+	oldRight, newRight, ok := verifySubProof(hash, proof, idx, m-k, n-k, false, oldRoot)
+	if !ok {
+		return nil, nil, false
+	}
+	if *idx >= len(proof) {
+		return nil, nil, false
+	}
+	left := proof[*idx]
+	*idx++
+
+	return hash(false, concat(left, oldRight)), hash(false, concat(left, newRight)), true
+}
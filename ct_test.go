@@ -0,0 +1,109 @@
+package merkletree
+
+import (
+	"testing"
+)
+
+func ctBlocks(words ...string) [][]byte {
+	blocks := make([][]byte, len(words))
+	for i, w := range words {
+		blocks[i] = []byte(w)
+	}
+	return blocks
+}
+
+func TestNewCTTree(t *testing.T) {
+	t.Run("leaves are never duplicated to balance odd levels", func(t *testing.T) {
+		blocks := ctBlocks("alpha", "beta", "kappa")
+		tree := NewCTTree(IdentityHashForTest, blocks)
+
+		label := bytesToStrForTest(tree.Root())
+		expected := "alphabetakappa"
+		if label != expected {
+			t.Fatalf("got root label %q, want %q", label, expected)
+		}
+	})
+
+	t.Run("single leaf tree's root is the leaf hash", func(t *testing.T) {
+		blocks := ctBlocks("alpha")
+		tree := NewCTTree(IdentityHashForTest, blocks)
+
+		if bytesToStrForTest(tree.Root()) != "alpha" {
+			t.Fail()
+		}
+	})
+}
+
+func TestCTTreeAuditProof(t *testing.T) {
+	blocks := ctBlocks("alpha", "beta", "kappa", "gamma", "epsilon")
+	tree := NewCTTree(Sha256DoubleHash, blocks)
+
+	for i := range blocks {
+		proof, err := tree.CreateProof(uint64(i))
+		if err != nil {
+			t.Fatalf("CreateProof(%d): %v", i, err)
+		}
+		if !tree.VerifyProof(proof) {
+			t.Fatalf("VerifyProof(%d) = false, want true", i)
+		}
+	}
+
+	t.Run("tampered sibling fails verification", func(t *testing.T) {
+		proof, _ := tree.CreateProof(0)
+		proof.parts[0].checksum = tree.checksumFunc(true, []byte("not-a-real-leaf"))
+
+		if tree.VerifyProof(proof) {
+			t.Fail()
+		}
+	})
+
+	t.Run("out of range index", func(t *testing.T) {
+		if _, err := tree.CreateProof(uint64(len(blocks))); err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestConsistencyProof(t *testing.T) {
+	blocks := ctBlocks("alpha", "beta", "kappa", "gamma", "epsilon", "omega", "mu")
+	tree := NewCTTree(Sha256DoubleHash, blocks)
+
+	for oldSize := uint64(1); oldSize < uint64(len(blocks)); oldSize++ {
+		for newSize := oldSize + 1; newSize <= uint64(len(blocks)); newSize++ {
+			oldTree := NewCTTree(Sha256DoubleHash, blocks[:oldSize])
+
+			proof, err := tree.CreateConsistencyProof(oldSize, newSize)
+			if err != nil {
+				t.Fatalf("CreateConsistencyProof(%d, %d): %v", oldSize, newSize, err)
+			}
+
+			newTree := NewCTTree(Sha256DoubleHash, blocks[:newSize])
+
+			if !VerifyConsistencyProof(Sha256DoubleHash, oldTree.Root(), newTree.Root(), oldSize, newSize, proof) {
+				t.Fatalf("VerifyConsistencyProof(%d, %d) = false, want true", oldSize, newSize)
+			}
+		}
+	}
+
+	t.Run("tampered proof fails verification", func(t *testing.T) {
+		oldTree := NewCTTree(Sha256DoubleHash, blocks[:3])
+		proof, _ := tree.CreateConsistencyProof(3, 7)
+		proof[0] = tree.checksumFunc(true, []byte("not-a-real-node"))
+
+		if VerifyConsistencyProof(Sha256DoubleHash, oldTree.Root(), tree.Root(), 3, 7, proof) {
+			t.Fail()
+		}
+	})
+
+	t.Run("oldSize zero is trivially consistent", func(t *testing.T) {
+		if !VerifyConsistencyProof(Sha256DoubleHash, nil, tree.Root(), 0, 7, nil) {
+			t.Fail()
+		}
+	})
+
+	t.Run("equal sizes require matching roots and an empty proof", func(t *testing.T) {
+		if !VerifyConsistencyProof(Sha256DoubleHash, tree.Root(), tree.Root(), 7, 7, nil) {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package merkletree
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToDOT renders the tree as a Graphviz DOT graph, with each node's
+// checksum rendered through labelFunc. Unlike ToString, every node gets
+// its own box even when two nodes share a checksum (as duplicated
+// leaves/branches do to balance the tree), so the duplication is
+// visible rather than collapsed away. Pipe the output through
+// `dot -Tsvg` to render it.
+func (t *Tree) ToDOT(labelFunc func([]byte) string) string {
+	var b strings.Builder
+
+	b.WriteString("digraph MerkleTree {\n")
+	b.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+
+	if t.root != nil {
+		id := 0
+		nodeToDOT(&b, t.root, labelFunc, &id)
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// nodeToDOT writes n and its descendants as DOT node/edge statements and
+// returns n's DOT node id.
+func nodeToDOT(b *strings.Builder, n *Node, labelFunc func([]byte) string, id *int) string {
+	nodeID := fmt.Sprintf("n%d", *id)
+	*id++
+
+	fmt.Fprintf(b, "  %s [label=%q];\n", nodeID, labelFunc(n.checksum))
+
+	if n.left != nil {
+		leftID := nodeToDOT(b, n.left, labelFunc, id)
+		rightID := nodeToDOT(b, n.right, labelFunc, id)
+		fmt.Fprintf(b, "  %s -> %s;\n", nodeID, leftID)
+		fmt.Fprintf(b, "  %s -> %s;\n", nodeID, rightID)
+	}
+
+	return nodeID
+}
+
+// ToDOT renders the audit path as a Graphviz DOT graph: the target leaf
+// (blue), each sibling hash the proof supplies (yellow), and each
+// reconstructed intermediate hash up to the root (green), with edges
+// showing how the siblings combine with the target on the way up.
+// Checksums are rendered through labelFunc.
+func (p *Proof) ToDOT(labelFunc func([]byte) string) string {
+	var b strings.Builder
+
+	b.WriteString("digraph AuditProof {\n")
+	b.WriteString("  node [shape=box, fontname=\"monospace\", style=filled];\n")
+
+	targetID := "n0"
+	fmt.Fprintf(&b, "  %s [label=%q, fillcolor=lightblue];\n", targetID, labelFunc(p.target))
+
+	current := p.target
+	currentID := targetID
+	for i, part := range p.parts {
+		siblingID := fmt.Sprintf("s%d", i)
+		fmt.Fprintf(&b, "  %s [label=%q, fillcolor=lightyellow];\n", siblingID, labelFunc(part.checksum))
+
+		var combined []byte
+		var leftID, rightID string
+		if part.isRight {
+			combined = p.checksumFunc(false, concat(current, part.checksum))
+			leftID, rightID = currentID, siblingID
+		} else {
+			combined = p.checksumFunc(false, concat(part.checksum, current))
+			leftID, rightID = siblingID, currentID
+		}
+
+		combinedID := fmt.Sprintf("h%d", i)
+		fmt.Fprintf(&b, "  %s [label=%q, fillcolor=lightgreen];\n", combinedID, labelFunc(combined))
+		fmt.Fprintf(&b, "  %s -> %s;\n", combinedID, leftID)
+		fmt.Fprintf(&b, "  %s -> %s;\n", combinedID, rightID)
+
+		current = combined
+		currentID = combinedID
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
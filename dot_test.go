@@ -0,0 +1,66 @@
+package merkletree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTreeToDOT(t *testing.T) {
+	blocks := [][]byte{[]byte("alpha"), []byte("beta"), []byte("kappa")}
+	tree := NewTree(IdentityHashForTest, blocks)
+
+	dot := tree.ToDOT(bytesToStrForTest)
+
+	if !strings.HasPrefix(dot, "digraph MerkleTree {\n") {
+		t.Fatalf("missing digraph header:\n%s", dot)
+	}
+	if !strings.HasSuffix(dot, "}\n") {
+		t.Fatalf("missing closing brace:\n%s", dot)
+	}
+
+	// 3 blocks balanced to 4 leaves (kappa duplicated) gives 4 leaves + 2
+	// level-1 branches + 1 root = 7 nodes, each its own box even though
+	// the duplicated kappa leaves share a checksum.
+	if got := strings.Count(dot, "[label="); got != 7 {
+		t.Fatalf("got %d node declarations, want 7", got)
+	}
+	if got := strings.Count(dot, `label="kappa"`); got != 2 {
+		t.Fatalf("got %d nodes labeled exactly kappa, want 2 (the duplicated leaf)", got)
+	}
+}
+
+func TestTreeToDOTEmpty(t *testing.T) {
+	tree := NewTree(IdentityHashForTest, nil)
+
+	dot := tree.ToDOT(bytesToStrForTest)
+
+	if strings.Contains(dot, "[label=") {
+		t.Fatalf("expected no node declarations for an empty tree:\n%s", dot)
+	}
+}
+
+func TestProofToDOT(t *testing.T) {
+	blocks := [][]byte{[]byte("alpha"), []byte("beta"), []byte("kappa")}
+	tree := NewTree(IdentityHashForTest, blocks)
+
+	target := tree.checksumFunc(true, []byte("alpha"))
+	proof, err := tree.CreateProof(target)
+	if err != nil {
+		t.Fatalf("CreateProof: %v", err)
+	}
+
+	dot := proof.ToDOT(bytesToStrForTest)
+
+	if !strings.HasPrefix(dot, "digraph AuditProof {\n") {
+		t.Fatalf("missing digraph header:\n%s", dot)
+	}
+	if !strings.Contains(dot, "fillcolor=lightblue") {
+		t.Fatal("target leaf not highlighted")
+	}
+	if got := strings.Count(dot, "fillcolor=lightyellow"); got != len(proof.parts) {
+		t.Fatalf("got %d sibling nodes, want %d", got, len(proof.parts))
+	}
+	if got := strings.Count(dot, "fillcolor=lightgreen"); got != len(proof.parts) {
+		t.Fatalf("got %d reconstructed nodes, want %d", got, len(proof.parts))
+	}
+}
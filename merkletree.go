@@ -0,0 +1,257 @@
+// Package merkletree implements a binary Merkle tree over an ordered list
+// of byte-slice blocks, with audit (inclusion) proof generation and
+// verification.
+//
+// Trees with an odd number of nodes at any level are balanced by
+// duplicating the last node of that level, matching the approach used by
+// Bitcoin and many other Merkle tree implementations. Leaf and branch
+// checksums are domain-separated (the ChecksumFunc receives an isLeaf
+// flag) so that an internal node's checksum can never be replayed as a
+// leaf checksum, which defeats the classic second-preimage attack against
+// Merkle trees.
+package merkletree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+// ChecksumFunc hashes data into a checksum. isLeaf is true when data is a
+// raw block and false when data is the concatenation of two child
+// checksums, allowing implementations to domain-separate the two cases.
+type ChecksumFunc func(isLeaf bool, data []byte) []byte
+
+// IdentityHashForTest is a ChecksumFunc that returns its input unchanged
+// apart from a one-byte isLeaf/branch prefix. It exists so tests can read
+// human-readable labels straight off checksum bytes.
+func IdentityHashForTest(isLeaf bool, data []byte) []byte {
+	return append([]byte{leafPrefix(isLeaf)}, data...)
+}
+
+// Sha256DoubleHash is a ChecksumFunc that applies SHA-256 twice, in the
+// style of Bitcoin's hashing, over the isLeaf-prefixed input.
+func Sha256DoubleHash(isLeaf bool, data []byte) []byte {
+	first := sha256.Sum256(append([]byte{leafPrefix(isLeaf)}, data...))
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+func leafPrefix(isLeaf bool) byte {
+	if isLeaf {
+		return 0x00
+	}
+	return 0x01
+}
+
+// Node is a single node of a Tree: a leaf if left and right are nil, a
+// branch otherwise.
+type Node struct {
+	checksum []byte
+	left     *Node
+	right    *Node
+}
+
+// GetChecksum returns the node's checksum.
+func (n *Node) GetChecksum() []byte {
+	return n.checksum
+}
+
+// Tree is a binary Merkle tree built from an ordered list of blocks.
+type Tree struct {
+	checksumFunc ChecksumFunc
+	root         *Node
+	rows         [][]*Node
+}
+
+// NewTree builds a Tree over blocks using hash to checksum leaves and
+// branches. Levels with an odd number of nodes are balanced by
+// duplicating the last node of that level.
+func NewTree(hash ChecksumFunc, blocks [][]byte) *Tree {
+	leaves := make([]*Node, len(blocks))
+	for i, block := range blocks {
+		leaves[i] = &Node{checksum: hash(true, block)}
+	}
+	if len(leaves) == 1 {
+		leaves = append(leaves, leaves[0])
+	}
+
+	t := &Tree{
+		checksumFunc: hash,
+		rows:         [][]*Node{leaves},
+	}
+	t.build()
+
+	return t
+}
+
+func (t *Tree) build() {
+	if len(t.rows[0]) == 0 {
+		return
+	}
+
+	for {
+		row := t.rows[len(t.rows)-1]
+		if len(row) == 1 {
+			break
+		}
+
+		if len(row)%2 == 1 {
+			row = append(row, row[len(row)-1])
+		}
+
+		next := make([]*Node, 0, len(row)/2)
+		for i := 0; i < len(row); i += 2 {
+			left, right := row[i], row[i+1]
+			checksum := t.checksumFunc(false, concat(left.checksum, right.checksum))
+			next = append(next, &Node{checksum: checksum, left: left, right: right})
+		}
+
+		t.rows = append(t.rows, next)
+	}
+
+	t.root = t.rows[len(t.rows)-1][0]
+}
+
+func concat(a, b []byte) []byte {
+	out := make([]byte, 0, len(a)+len(b))
+	out = append(out, a...)
+	out = append(out, b...)
+	return out
+}
+
+// ToString renders the tree as an s-expression, indented by indent levels,
+// with each node's checksum rendered through labelFunc.
+func (t *Tree) ToString(labelFunc func([]byte) string, indent int) string {
+	if t.root == nil {
+		return ""
+	}
+
+	return nodeToString(t.root, labelFunc, indent)
+}
+
+func nodeToString(n *Node, labelFunc func([]byte) string, indent int) string {
+	prefix := strings.Repeat("  ", indent)
+
+	if n.left == nil && n.right == nil {
+		return fmt.Sprintf("%s(L root: %s)", prefix, labelFunc(n.checksum))
+	}
+
+	return fmt.Sprintf("%s(B root: %s \n%s \n%s)", prefix, labelFunc(n.checksum),
+		nodeToString(n.left, labelFunc, indent+1),
+		nodeToString(n.right, labelFunc, indent+1))
+}
+
+// ProofPart is one step of an audit proof: the sibling checksum needed at
+// that level, and whether it sits to the right of the node being proved.
+type ProofPart struct {
+	isRight  bool
+	checksum []byte
+}
+
+// Proof is an audit (inclusion) proof for a single leaf checksum.
+type Proof struct {
+	parts        []*ProofPart
+	target       []byte
+	checksumFunc ChecksumFunc
+}
+
+// Equals reports whether two proofs prove the same target via the same
+// sequence of sibling checksums.
+func (p *Proof) Equals(other *Proof) bool {
+	if other == nil || len(p.parts) != len(other.parts) || !bytes.Equal(p.target, other.target) {
+		return false
+	}
+
+	for i, part := range p.parts {
+		o := other.parts[i]
+		if part.isRight != o.isRight || !bytes.Equal(part.checksum, o.checksum) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ToString renders the proof as a step-by-step route from the leaf to the
+// root, with each checksum rendered through labelFunc.
+func (p *Proof) ToString(labelFunc func([]byte) string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "route from %s (leaf) to root:\n\n", labelFunc(p.target))
+
+	current := p.target
+	for _, part := range p.parts {
+		var combined []byte
+		if part.isRight {
+			combined = p.checksumFunc(false, concat(current, part.checksum))
+			fmt.Fprintf(&b, "%s + %s = %s\n", labelFunc(current), labelFunc(part.checksum), labelFunc(combined))
+		} else {
+			combined = p.checksumFunc(false, concat(part.checksum, current))
+			fmt.Fprintf(&b, "%s + %s = %s\n", labelFunc(part.checksum), labelFunc(current), labelFunc(combined))
+		}
+		current = combined
+	}
+
+	return b.String()
+}
+
+// CreateProof builds an audit proof that target (a leaf checksum) is
+// included in the tree.
+func (t *Tree) CreateProof(target []byte) (*Proof, error) {
+	idx := -1
+	for i, n := range t.rows[0] {
+		if bytes.Equal(n.checksum, target) {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, fmt.Errorf("merkletree: target checksum not found among leaves")
+	}
+
+	return t.proofAt(idx), nil
+}
+
+// proofAt builds an audit proof for the leaf at position idx in
+// t.rows[0], walking the path to the root by index rather than by
+// re-deriving idx from a checksum. Callers that already know a leaf's
+// position (rather than just its checksum) should use this instead of
+// CreateProof, since a checksum can be shared by more than one leaf.
+func (t *Tree) proofAt(idx int) *Proof {
+	target := t.rows[0][idx].checksum
+
+	var parts []*ProofPart
+	for _, row := range t.rows[:len(t.rows)-1] {
+		switch {
+		case idx == len(row)-1 && len(row)%2 == 1:
+			parts = append(parts, &ProofPart{isRight: true, checksum: row[idx].checksum})
+		case idx%2 == 0:
+			parts = append(parts, &ProofPart{isRight: true, checksum: row[idx+1].checksum})
+		default:
+			parts = append(parts, &ProofPart{isRight: false, checksum: row[idx-1].checksum})
+		}
+		idx /= 2
+	}
+
+	return &Proof{parts: parts, target: target, checksumFunc: t.checksumFunc}
+}
+
+// VerifyProof reports whether proof reconstructs the tree's root checksum.
+func (t *Tree) VerifyProof(proof *Proof) bool {
+	if t.root == nil {
+		return false
+	}
+
+	current := proof.target
+	for _, part := range proof.parts {
+		if part.isRight {
+			current = t.checksumFunc(false, concat(current, part.checksum))
+		} else {
+			current = t.checksumFunc(false, concat(part.checksum, current))
+		}
+	}
+
+	return bytes.Equal(current, t.root.checksum)
+}
@@ -0,0 +1,201 @@
+package merkletree
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// MultiProof is an audit proof that a set of leaf checksums are all
+// included in a tree, sharing the sibling hashes that sit on more than
+// one target's path so the proof is smaller than the sum of the
+// equivalent single-target Proofs.
+type MultiProof struct {
+	indices      []uint64
+	targets      [][]byte
+	siblings     [][]byte
+	numLeaves    uint64
+	checksumFunc ChecksumFunc
+}
+
+// CreateMultiProof builds an audit proof that every checksum in targets
+// (a set of leaf checksums, order-independent and may contain
+// duplicates) is included in the tree.
+func (t *Tree) CreateMultiProof(targets [][]byte) (*MultiProof, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("merkletree: no targets given")
+	}
+
+	seen := make(map[uint64]bool, len(targets))
+	var indices []uint64
+	var checksums [][]byte
+	for _, target := range targets {
+		idx := -1
+		for i, n := range t.rows[0] {
+			if bytes.Equal(n.checksum, target) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf("merkletree: target checksum not found among leaves")
+		}
+
+		if seen[uint64(idx)] {
+			continue
+		}
+		seen[uint64(idx)] = true
+		indices = append(indices, uint64(idx))
+		checksums = append(checksums, target)
+	}
+
+	sort.Sort(&indexSort{indices: indices, checksums: checksums})
+
+	current := make(map[uint64][]byte, len(indices))
+	for i, idx := range indices {
+		current[idx] = checksums[i]
+	}
+
+	var siblings [][]byte
+	for level := 0; len(t.rows[level]) > 1; level++ {
+		row := t.rows[level]
+		next := make(map[uint64][]byte)
+
+		for _, idx := range sortedUint64Keys(current) {
+			parent, left, right, needSibling := splitPair(idx, current, uint64(len(row)))
+			if needSibling {
+				sibling := row[siblingIndex(idx)].checksum
+				siblings = append(siblings, sibling)
+				if idx%2 == 0 {
+					right = sibling
+				} else {
+					left = sibling
+				}
+			}
+
+			if _, already := next[parent]; already {
+				continue
+			}
+			next[parent] = t.checksumFunc(false, concat(left, right))
+		}
+
+		current = next
+	}
+
+	return &MultiProof{
+		indices:      indices,
+		targets:      checksums,
+		siblings:     siblings,
+		numLeaves:    uint64(len(t.rows[0])),
+		checksumFunc: t.checksumFunc,
+	}, nil
+}
+
+// VerifyMultiProof reports whether mp reconstructs the tree's root
+// checksum.
+func (t *Tree) VerifyMultiProof(mp *MultiProof) bool {
+	if t.root == nil || len(mp.indices) == 0 || len(mp.indices) != len(mp.targets) {
+		return false
+	}
+
+	current := make(map[uint64][]byte, len(mp.indices))
+	for i, idx := range mp.indices {
+		current[idx] = mp.targets[i]
+	}
+
+	siblings := mp.siblings
+	rowLen := mp.numLeaves
+	for rowLen > 1 {
+		next := make(map[uint64][]byte)
+
+		for _, idx := range sortedUint64Keys(current) {
+			parent, left, right, needSibling := splitPair(idx, current, rowLen)
+			if needSibling {
+				if len(siblings) == 0 {
+					return false
+				}
+				sibling := siblings[0]
+				siblings = siblings[1:]
+				if idx%2 == 0 {
+					right = sibling
+				} else {
+					left = sibling
+				}
+			}
+
+			if _, already := next[parent]; already {
+				continue
+			}
+			next[parent] = mp.checksumFunc(false, concat(left, right))
+		}
+
+		current = next
+		rowLen = (rowLen + 1) / 2
+	}
+
+	if len(siblings) != 0 || len(current) != 1 {
+		return false
+	}
+
+	return bytes.Equal(current[0], t.root.checksum)
+}
+
+// splitPair looks at idx's position among the already-known hashes in
+// current and reports which of its pair is already known: if idx's
+// sibling is also in current, both sides are returned directly and
+// needSibling is false; otherwise only the side contributed by idx is
+// filled in and needSibling is true, telling the caller to supply the
+// other side (from the tree, when building a proof, or from the proof's
+// sibling stream, when verifying one). rowLen is the number of nodes in
+// idx's row, needed to detect the odd-row self-duplicate case.
+func splitPair(idx uint64, current map[uint64][]byte, rowLen uint64) (parent uint64, left, right []byte, needSibling bool) {
+	parent = idx / 2
+
+	if idx%2 == 0 {
+		left = current[idx]
+		if idx+1 >= rowLen {
+			return parent, left, left, false
+		}
+		if sib, ok := current[idx+1]; ok {
+			return parent, left, sib, false
+		}
+		return parent, left, nil, true
+	}
+
+	right = current[idx]
+	if sib, ok := current[idx-1]; ok {
+		return parent, sib, right, false
+	}
+	return parent, nil, right, true
+}
+
+// siblingIndex returns the row index of idx's sibling; only valid when
+// idx isn't the self-duplicated last node of an odd-length row.
+func siblingIndex(idx uint64) uint64 {
+	if idx%2 == 0 {
+		return idx + 1
+	}
+	return idx - 1
+}
+
+func sortedUint64Keys(m map[uint64][]byte) []uint64 {
+	keys := make([]uint64, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// indexSort sorts a parallel (indices, checksums) pair by index.
+type indexSort struct {
+	indices   []uint64
+	checksums [][]byte
+}
+
+func (s *indexSort) Len() int           { return len(s.indices) }
+func (s *indexSort) Less(i, j int) bool { return s.indices[i] < s.indices[j] }
+func (s *indexSort) Swap(i, j int) {
+	s.indices[i], s.indices[j] = s.indices[j], s.indices[i]
+	s.checksums[i], s.checksums[j] = s.checksums[j], s.checksums[i]
+}
@@ -0,0 +1,103 @@
+package merkletree
+
+import "testing"
+
+func TestMultiProof(t *testing.T) {
+	words := []string{"alpha", "beta", "kappa", "gamma", "epsilon", "omega", "mu", "zeta", "eta"}
+
+	for n := 1; n <= len(words); n++ {
+		blocks := make([][]byte, n)
+		for i := 0; i < n; i++ {
+			blocks[i] = []byte(words[i])
+		}
+
+		tree := NewTree(Sha256DoubleHash, blocks)
+
+		t.Run("every subset of targets verifies", func(t *testing.T) {
+			for mask := 1; mask < (1 << n); mask++ {
+				var targets [][]byte
+				for i := 0; i < n; i++ {
+					if mask&(1<<i) != 0 {
+						targets = append(targets, tree.checksumFunc(true, blocks[i]))
+					}
+				}
+
+				mp, err := tree.CreateMultiProof(targets)
+				if err != nil {
+					t.Fatalf("n=%d mask=%d: CreateMultiProof: %v", n, mask, err)
+				}
+
+				if !tree.VerifyMultiProof(mp) {
+					t.Fatalf("n=%d mask=%d: VerifyMultiProof = false", n, mask)
+				}
+			}
+		})
+	}
+}
+
+func TestMultiProofDedupesSharedSiblings(t *testing.T) {
+	blocks := [][]byte{
+		[]byte("alpha"),
+		[]byte("beta"),
+		[]byte("kappa"),
+		[]byte("gamma"),
+	}
+	tree := NewTree(Sha256DoubleHash, blocks)
+
+	targets := [][]byte{
+		tree.checksumFunc(true, []byte("alpha")),
+		tree.checksumFunc(true, []byte("beta")),
+	}
+
+	mp, err := tree.CreateMultiProof(targets)
+	if err != nil {
+		t.Fatalf("CreateMultiProof: %v", err)
+	}
+
+	// alpha and beta are siblings, so no sibling hashes are needed below
+	// their shared parent: only the other half of the tree (kappa+gamma's
+	// combined hash) is required to reach the root.
+	if len(mp.siblings) != 1 {
+		t.Fatalf("got %d sibling hashes, want 1", len(mp.siblings))
+	}
+
+	if !tree.VerifyMultiProof(mp) {
+		t.Fatal("VerifyMultiProof = false")
+	}
+}
+
+func TestMultiProofRejectsTamperedTarget(t *testing.T) {
+	blocks := [][]byte{
+		[]byte("alpha"),
+		[]byte("beta"),
+		[]byte("kappa"),
+	}
+	tree := NewTree(Sha256DoubleHash, blocks)
+
+	mp, err := tree.CreateMultiProof([][]byte{
+		tree.checksumFunc(true, []byte("alpha")),
+		tree.checksumFunc(true, []byte("kappa")),
+	})
+	if err != nil {
+		t.Fatalf("CreateMultiProof: %v", err)
+	}
+
+	mp.targets[0] = tree.checksumFunc(true, []byte("beta"))
+
+	if tree.VerifyMultiProof(mp) {
+		t.Fatal("VerifyMultiProof = true for a tampered target")
+	}
+}
+
+func TestMultiProofRejectsUnknownTarget(t *testing.T) {
+	blocks := [][]byte{
+		[]byte("alpha"),
+		[]byte("beta"),
+	}
+	tree := NewTree(Sha256DoubleHash, blocks)
+
+	_, err := tree.CreateMultiProof([][]byte{tree.checksumFunc(true, []byte("kappa"))})
+	if err == nil {
+		t.Fatal("CreateMultiProof: want error for an unknown target")
+	}
+}
@@ -0,0 +1,186 @@
+package merkletree
+
+import "sync"
+
+// NewTreeParallel builds a Tree exactly as NewTree does, but spreads the
+// work across up to nCPU worker goroutines. nCPU values less than 1 are
+// treated as 1.
+//
+// When len(blocks) is a power of two, building is embarrassingly
+// parallel: the leaves are bucketed into contiguous ranges (one per
+// worker), each worker builds its bucket's sub-root in isolation with a
+// single pass of build, and the sub-roots are then combined serially —
+// exactly one synchronization barrier for the whole tree. That only
+// works out evenly when the leaf count is a power of two; for any other
+// count, NewTree's odd-row duplication rule can land the tree's
+// balancing point inside a bucket in a way an independent per-bucket
+// build can't reproduce (a bucket built alone has no way to know it
+// needs to duplicate its last node to match what the full tree would
+// have done), so buildParallel's level-synchronized approach — one
+// barrier per row instead of one total, but always correct — is used
+// instead. Either way the resulting root (and the tree's ToString,
+// CreateProof, and VerifyProof behavior) always matches NewTree's output
+// for the same input.
+func NewTreeParallel(hash ChecksumFunc, blocks [][]byte, nCPU int) *Tree {
+	if nCPU < 1 {
+		nCPU = 1
+	}
+
+	if rows, ok := buildBucketed(hash, blocks, nCPU); ok {
+		t := &Tree{checksumFunc: hash, rows: rows}
+		t.root = rows[len(rows)-1][0]
+		return t
+	}
+
+	leaves := make([]*Node, len(blocks))
+	parallelFor(len(leaves), nCPU, func(i int) {
+		leaves[i] = &Node{checksum: hash(true, blocks[i])}
+	})
+	if len(leaves) == 1 {
+		leaves = append(leaves, leaves[0])
+	}
+
+	t := &Tree{
+		checksumFunc: hash,
+		rows:         [][]*Node{leaves},
+	}
+	t.buildParallel(nCPU)
+
+	return t
+}
+
+// buildBucketed builds a Tree's rows by splitting blocks into
+// numBuckets (the largest power of two that's <= nCPU and <= len(blocks))
+// contiguous, equal-sized buckets, building each bucket's rows in its own
+// goroutine with plain build (a single barrier for all of them), then
+// combining the buckets' sub-roots into the remaining rows serially.
+// Because len(blocks) is a power of two here, every bucket is itself a
+// power of two in size, so build never has to duplicate a node inside a
+// bucket — each bucket's rows are exactly the corresponding slice of
+// what a serial NewTree would have produced, and concatenating them
+// back together in order reconstructs the full tree. It reports ok=false
+// when len(blocks) isn't a power of two (or is too small to split), and
+// the caller should fall back to the level-synchronized builder.
+func buildBucketed(hash ChecksumFunc, blocks [][]byte, nCPU int) (rows [][]*Node, ok bool) {
+	n := len(blocks)
+	if n < 2 || n&(n-1) != 0 {
+		return nil, false
+	}
+
+	numBuckets := 1
+	for numBuckets*2 <= nCPU && numBuckets*2 <= n {
+		numBuckets *= 2
+	}
+	if numBuckets < 2 {
+		return nil, false
+	}
+	bucketSize := n / numBuckets
+
+	bucketRows := make([][][]*Node, numBuckets)
+	var wg sync.WaitGroup
+	for b := 0; b < numBuckets; b++ {
+		wg.Add(1)
+		go func(b int) {
+			defer wg.Done()
+			leaves := make([]*Node, bucketSize)
+			for i, block := range blocks[b*bucketSize : (b+1)*bucketSize] {
+				leaves[i] = &Node{checksum: hash(true, block)}
+			}
+			bt := &Tree{checksumFunc: hash, rows: [][]*Node{leaves}}
+			bt.build()
+			bucketRows[b] = bt.rows
+		}(b)
+	}
+	wg.Wait()
+
+	height := len(bucketRows[0])
+	rows = make([][]*Node, height)
+	for level := 0; level < height; level++ {
+		var row []*Node
+		for b := 0; b < numBuckets; b++ {
+			row = append(row, bucketRows[b][level]...)
+		}
+		rows[level] = row
+	}
+
+	for row := rows[len(rows)-1]; len(row) > 1; {
+		next := make([]*Node, len(row)/2)
+		for i := range next {
+			left, right := row[2*i], row[2*i+1]
+			next[i] = &Node{checksum: hash(false, concat(left.checksum, right.checksum)), left: left, right: right}
+		}
+		rows = append(rows, next)
+		row = next
+	}
+
+	return rows, true
+}
+
+// buildParallel is the parallel counterpart to build: it produces the
+// same rows, level by level, but hashes each row's sibling pairs across
+// nCPU worker goroutines instead of in a single loop. Used when
+// buildBucketed can't apply (see NewTreeParallel).
+func (t *Tree) buildParallel(nCPU int) {
+	if len(t.rows[0]) == 0 {
+		return
+	}
+
+	for {
+		row := t.rows[len(t.rows)-1]
+		if len(row) == 1 {
+			break
+		}
+
+		if len(row)%2 == 1 {
+			row = append(row, row[len(row)-1])
+		}
+
+		next := make([]*Node, len(row)/2)
+		parallelFor(len(next), nCPU, func(i int) {
+			left, right := row[2*i], row[2*i+1]
+			checksum := t.checksumFunc(false, concat(left.checksum, right.checksum))
+			next[i] = &Node{checksum: checksum, left: left, right: right}
+		})
+
+		t.rows = append(t.rows, next)
+	}
+
+	t.root = t.rows[len(t.rows)-1][0]
+}
+
+// parallelFor calls fn(i) for every i in [0, n), splitting the range into
+// up to nCPU contiguous buckets run on their own goroutine, and blocks
+// until every bucket has finished.
+func parallelFor(n, nCPU int, fn func(i int)) {
+	if n == 0 {
+		return
+	}
+	if nCPU > n {
+		nCPU = n
+	}
+	if nCPU <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	bucketSize := (n + nCPU - 1) / nCPU
+
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += bucketSize {
+		end := start + bucketSize
+		if end > n {
+			end = n
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				fn(i)
+			}
+		}(start, end)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,124 @@
+package merkletree
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestNewTreeParallel(t *testing.T) {
+	words := []string{"alpha", "beta", "kappa", "gamma", "epsilon", "omega", "mu", "zeta", "eta"}
+
+	for n := 1; n <= len(words); n++ {
+		blocks := make([][]byte, n)
+		for i := 0; i < n; i++ {
+			blocks[i] = []byte(words[i])
+		}
+
+		want := NewTree(Sha256DoubleHash, blocks)
+
+		for _, nCPU := range []int{0, 1, 2, 3, 4, 8} {
+			got := NewTreeParallel(Sha256DoubleHash, blocks, nCPU)
+
+			if !bytes.Equal(got.root.GetChecksum(), want.root.GetChecksum()) {
+				t.Fatalf("n=%d nCPU=%d: got root %x, want %x", n, nCPU, got.root.GetChecksum(), want.root.GetChecksum())
+			}
+		}
+	}
+}
+
+func TestNewTreeParallelProofsMatch(t *testing.T) {
+	blocks := [][]byte{
+		[]byte("alpha"),
+		[]byte("beta"),
+		[]byte("kappa"),
+		[]byte("gamma"),
+		[]byte("epsilon"),
+	}
+
+	want := NewTree(Sha256DoubleHash, blocks)
+	got := NewTreeParallel(Sha256DoubleHash, blocks, 4)
+
+	for _, block := range blocks {
+		target := got.checksumFunc(true, block)
+
+		wantProof, err := want.CreateProof(target)
+		if err != nil {
+			t.Fatalf("NewTree.CreateProof: %v", err)
+		}
+
+		gotProof, err := got.CreateProof(target)
+		if err != nil {
+			t.Fatalf("NewTreeParallel.CreateProof: %v", err)
+		}
+
+		if !wantProof.Equals(gotProof) {
+			t.Fatalf("proof for %q: parallel tree produced a different proof than the serial tree", block)
+		}
+
+		if !got.VerifyProof(gotProof) {
+			t.Fatalf("proof for %q: VerifyProof = false on parallel tree", block)
+		}
+	}
+}
+
+func TestNewTreeParallelPowerOfTwoUsesBucketedPath(t *testing.T) {
+	for _, n := range []int{2, 4, 8, 16, 64} {
+		blocks := benchmarkBlocks(n)
+		want := NewTree(Sha256DoubleHash, blocks)
+
+		for _, nCPU := range []int{1, 2, 3, 4, 8} {
+			got := NewTreeParallel(Sha256DoubleHash, blocks, nCPU)
+
+			if !bytes.Equal(got.root.GetChecksum(), want.root.GetChecksum()) {
+				t.Fatalf("n=%d nCPU=%d: got root %x, want %x", n, nCPU, got.root.GetChecksum(), want.root.GetChecksum())
+			}
+
+			for _, block := range blocks {
+				target := got.checksumFunc(true, block)
+
+				wantProof, err := want.CreateProof(target)
+				if err != nil {
+					t.Fatalf("n=%d nCPU=%d: NewTree.CreateProof: %v", n, nCPU, err)
+				}
+				gotProof, err := got.CreateProof(target)
+				if err != nil {
+					t.Fatalf("n=%d nCPU=%d: NewTreeParallel.CreateProof: %v", n, nCPU, err)
+				}
+				if !wantProof.Equals(gotProof) {
+					t.Fatalf("n=%d nCPU=%d block=%q: bucketed tree produced a different proof than the serial tree", n, nCPU, block)
+				}
+			}
+		}
+	}
+}
+
+func benchmarkBlocks(n int) [][]byte {
+	blocks := make([][]byte, n)
+	for i := range blocks {
+		blocks[i] = []byte(fmt.Sprintf("block-%d", i))
+	}
+	return blocks
+}
+
+func BenchmarkNewTree_1M(b *testing.B) {
+	blocks := benchmarkBlocks(1 << 20)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		NewTree(Sha256DoubleHash, blocks)
+	}
+}
+
+func BenchmarkNewTreeParallel_1M(b *testing.B) {
+	blocks := benchmarkBlocks(1 << 20)
+
+	for _, nCPU := range []int{2, 4, 8, 16} {
+		b.Run(fmt.Sprintf("nCPU=%d", nCPU), func(b *testing.B) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				NewTreeParallel(Sha256DoubleHash, blocks, nCPU)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package merkletree
+
+import "errors"
+
+// ErrNotFound is returned by a Storage's Get when key isn't present.
+var ErrNotFound = errors.New("merkletree: key not found in storage")
+
+// Storage persists a StorageTree's nodes so that a tree's nodes don't all
+// need to live in memory at once, letting a tree grow far larger than
+// RAM allows. Get must return ErrNotFound (or an error that wraps it)
+// when key hasn't been written.
+type Storage interface {
+	Get(key []byte) ([]byte, error)
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	NewBatch() Batch
+}
+
+// Batch groups a set of Storage writes so a backend can apply them
+// together. Put and Delete only take effect once Commit is called.
+type Batch interface {
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	Commit() error
+}
@@ -0,0 +1,116 @@
+// Package boltstore implements merkletree.Storage on top of BoltDB
+// (go.etcd.io/bbolt), for Merkle trees with more leaves than fit in
+// memory. All node and position records live in a single bucket.
+package boltstore
+
+import (
+	"fmt"
+
+	merkletree "github.com/hashaltcoin/go-merkle-tree"
+	bolt "go.etcd.io/bbolt"
+)
+
+var bucketName = []byte("merkletree")
+
+// Store is a merkletree.Storage backed by a BoltDB database.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens (creating if necessary) a BoltDB database at path and
+// returns a Store backed by it. Close the returned Store when done.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("boltstore: opening %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketName)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("boltstore: creating bucket: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying BoltDB database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Get implements merkletree.Storage.
+func (s *Store) Get(key []byte) ([]byte, error) {
+	var value []byte
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucketName).Get(key)
+		if v == nil {
+			return merkletree.ErrNotFound
+		}
+		value = append([]byte{}, v...)
+		return nil
+	})
+
+	return value, err
+}
+
+// Put implements merkletree.Storage.
+func (s *Store) Put(key, value []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Put(key, value)
+	})
+}
+
+// Delete implements merkletree.Storage.
+func (s *Store) Delete(key []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Delete(key)
+	})
+}
+
+// NewBatch implements merkletree.Storage.
+func (s *Store) NewBatch() merkletree.Batch {
+	return &batch{store: s}
+}
+
+// batch buffers writes and applies them in a single BoltDB transaction
+// on Commit.
+type batch struct {
+	store   *Store
+	puts    [][2][]byte
+	deletes [][]byte
+}
+
+func (b *batch) Put(key, value []byte) error {
+	b.puts = append(b.puts, [2][]byte{
+		append([]byte{}, key...),
+		append([]byte{}, value...),
+	})
+	return nil
+}
+
+func (b *batch) Delete(key []byte) error {
+	b.deletes = append(b.deletes, append([]byte{}, key...))
+	return nil
+}
+
+func (b *batch) Commit() error {
+	return b.store.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+		for _, kv := range b.puts {
+			if err := bucket.Put(kv[0], kv[1]); err != nil {
+				return err
+			}
+		}
+		for _, key := range b.deletes {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
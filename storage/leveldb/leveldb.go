@@ -0,0 +1,78 @@
+// Package leveldbstore implements merkletree.Storage on top of LevelDB
+// (github.com/syndtr/goleveldb), for Merkle trees with more leaves than
+// fit in memory.
+package leveldbstore
+
+import (
+	"fmt"
+
+	merkletree "github.com/hashaltcoin/go-merkle-tree"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// Store is a merkletree.Storage backed by a LevelDB database.
+type Store struct {
+	db *leveldb.DB
+}
+
+// Open opens (creating if necessary) a LevelDB database at path and
+// returns a Store backed by it. Close the returned Store when done.
+func Open(path string) (*Store, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("leveldbstore: opening %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying LevelDB database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Get implements merkletree.Storage.
+func (s *Store) Get(key []byte) ([]byte, error) {
+	value, err := s.db.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, merkletree.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Put implements merkletree.Storage.
+func (s *Store) Put(key, value []byte) error {
+	return s.db.Put(key, value, nil)
+}
+
+// Delete implements merkletree.Storage.
+func (s *Store) Delete(key []byte) error {
+	return s.db.Delete(key, nil)
+}
+
+// NewBatch implements merkletree.Storage.
+func (s *Store) NewBatch() merkletree.Batch {
+	return &batch{store: s, raw: new(leveldb.Batch)}
+}
+
+// batch wraps a leveldb.Batch, applying it to the store on Commit.
+type batch struct {
+	store *Store
+	raw   *leveldb.Batch
+}
+
+func (b *batch) Put(key, value []byte) error {
+	b.raw.Put(key, value)
+	return nil
+}
+
+func (b *batch) Delete(key []byte) error {
+	b.raw.Delete(key)
+	return nil
+}
+
+func (b *batch) Commit() error {
+	return b.store.db.Write(b.raw, nil)
+}
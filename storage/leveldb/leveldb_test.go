@@ -0,0 +1,60 @@
+package leveldbstore_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	merkletree "github.com/hashaltcoin/go-merkle-tree"
+	leveldbstore "github.com/hashaltcoin/go-merkle-tree/storage/leveldb"
+)
+
+func openTestStore(t *testing.T) *leveldbstore.Store {
+	t.Helper()
+
+	store, err := leveldbstore.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	blocks := [][]byte{[]byte("alpha"), []byte("beta"), []byte("kappa")}
+
+	tree := merkletree.NewTreeWithStorage(merkletree.Sha256DoubleHash, openTestStore(t))
+	if err := tree.AddBatch(blocks); err != nil {
+		t.Fatalf("AddBatch: %v", err)
+	}
+
+	proof, err := tree.CreateProof(1)
+	if err != nil {
+		t.Fatalf("CreateProof: %v", err)
+	}
+	if !tree.VerifyProof(proof) {
+		t.Fatal("VerifyProof = false")
+	}
+}
+
+func TestStoreGetMissingKey(t *testing.T) {
+	store := openTestStore(t)
+
+	if _, err := store.Get([]byte("missing")); err != merkletree.ErrNotFound {
+		t.Fatalf("got error %v, want merkletree.ErrNotFound", err)
+	}
+}
+
+func TestStoreDelete(t *testing.T) {
+	store := openTestStore(t)
+
+	if err := store.Put([]byte("k"), []byte("v")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := store.Delete([]byte("k")); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get([]byte("k")); err != merkletree.ErrNotFound {
+		t.Fatalf("got error %v after delete, want merkletree.ErrNotFound", err)
+	}
+}
@@ -0,0 +1,98 @@
+// Package memstore implements merkletree.Storage backed by an in-memory
+// map. It's the simplest Storage and is handy for tests and small
+// trees, but it keeps every node in RAM, so it doesn't help a tree grow
+// past what fits in memory the way the bolt and leveldb adapters do.
+package memstore
+
+import (
+	"sync"
+
+	"github.com/hashaltcoin/go-merkle-tree"
+)
+
+// Store is a merkletree.Storage backed by an in-memory map. The zero
+// value is not usable; use New.
+type Store struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+// New creates an empty Store.
+func New() *Store {
+	return &Store{data: make(map[string][]byte)}
+}
+
+// Get implements merkletree.Storage.
+func (s *Store) Get(key []byte) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := s.data[string(key)]
+	if !ok {
+		return nil, merkletree.ErrNotFound
+	}
+
+	out := make([]byte, len(v))
+	copy(out, v)
+	return out, nil
+}
+
+// Put implements merkletree.Storage.
+func (s *Store) Put(key, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v := make([]byte, len(value))
+	copy(v, value)
+	s.data[string(key)] = v
+	return nil
+}
+
+// Delete implements merkletree.Storage.
+func (s *Store) Delete(key []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, string(key))
+	return nil
+}
+
+// NewBatch implements merkletree.Storage.
+func (s *Store) NewBatch() merkletree.Batch {
+	return &batch{store: s}
+}
+
+// batch buffers writes until Commit applies them to the store under a
+// single lock.
+type batch struct {
+	store   *Store
+	puts    [][2][]byte
+	deletes [][]byte
+}
+
+func (b *batch) Put(key, value []byte) error {
+	b.puts = append(b.puts, [2][]byte{
+		append([]byte{}, key...),
+		append([]byte{}, value...),
+	})
+	return nil
+}
+
+func (b *batch) Delete(key []byte) error {
+	b.deletes = append(b.deletes, append([]byte{}, key...))
+	return nil
+}
+
+func (b *batch) Commit() error {
+	b.store.mu.Lock()
+	defer b.store.mu.Unlock()
+
+	for _, kv := range b.puts {
+		b.store.data[string(kv[0])] = kv[1]
+	}
+	for _, key := range b.deletes {
+		delete(b.store.data, string(key))
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package memstore_test
+
+import (
+	"bytes"
+	"testing"
+
+	merkletree "github.com/hashaltcoin/go-merkle-tree"
+	"github.com/hashaltcoin/go-merkle-tree/storage/memstore"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	blocks := [][]byte{[]byte("alpha"), []byte("beta"), []byte("kappa")}
+
+	tree := merkletree.NewTreeWithStorage(merkletree.Sha256DoubleHash, memstore.New())
+	if err := tree.AddBatch(blocks); err != nil {
+		t.Fatalf("AddBatch: %v", err)
+	}
+
+	proof, err := tree.CreateProof(1)
+	if err != nil {
+		t.Fatalf("CreateProof: %v", err)
+	}
+	if !tree.VerifyProof(proof) {
+		t.Fatal("VerifyProof = false")
+	}
+}
+
+func TestStoreGetMissingKey(t *testing.T) {
+	s := memstore.New()
+
+	if _, err := s.Get([]byte("missing")); err != merkletree.ErrNotFound {
+		t.Fatalf("got error %v, want merkletree.ErrNotFound", err)
+	}
+}
+
+func TestStoreDelete(t *testing.T) {
+	s := memstore.New()
+
+	if err := s.Put([]byte("k"), []byte("v")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Delete([]byte("k")); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get([]byte("k")); err != merkletree.ErrNotFound {
+		t.Fatalf("got error %v after delete, want merkletree.ErrNotFound", err)
+	}
+}
+
+func TestBatchIsolatedUntilCommit(t *testing.T) {
+	s := memstore.New()
+	batch := s.NewBatch()
+
+	if err := batch.Put([]byte("k"), []byte("v")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := s.Get([]byte("k")); err != merkletree.ErrNotFound {
+		t.Fatal("batch write visible before Commit")
+	}
+
+	if err := batch.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	got, err := s.Get([]byte("k"))
+	if err != nil {
+		t.Fatalf("Get after Commit: %v", err)
+	}
+	if !bytes.Equal(got, []byte("v")) {
+		t.Fatalf("got %q, want %q", got, "v")
+	}
+}
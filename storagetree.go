@@ -0,0 +1,375 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// StorageTree is a Tree whose nodes are kept in a Storage backend instead
+// of an in-memory rows slice, so the number of leaves isn't bounded by
+// RAM. Every node (leaf or branch) is persisted keyed by its own
+// checksum, and a second, position-keyed index (level, column) is
+// maintained alongside it so that CreateProof can fetch exactly the
+// sibling checksums an audit path needs instead of scanning the tree.
+//
+// Add and AddBatch only ever touch the O(log n) ancestors of the leaves
+// being added: a new leaf is paired with its already-persisted sibling
+// as soon as one exists, and the combined checksum is persisted and
+// carried one level up, exactly as NewTree's odd-row duplication would
+// produce if the whole tree were rebuilt from scratch. The one node per
+// level that's still waiting for a sibling (because the tree currently
+// has an odd count at that level) is never persisted as duplicated-with-
+// itself, since a later Add can still give it a real sibling; Root and
+// CreateProof instead derive that duplicate on the fly from the real
+// node it would duplicate.
+type StorageTree struct {
+	checksumFunc ChecksumFunc
+	store        Storage
+	root         []byte
+	numLeaves    uint64
+}
+
+// NewTreeWithStorage creates an empty StorageTree backed by store. Use
+// Add or AddBatch to populate it.
+func NewTreeWithStorage(hash ChecksumFunc, store Storage) *StorageTree {
+	return &StorageTree{checksumFunc: hash, store: store}
+}
+
+// Root returns the tree's root checksum, or nil if the tree has no
+// leaves yet.
+func (st *StorageTree) Root() []byte {
+	return st.root
+}
+
+// NumLeaves returns the number of leaves added to the tree so far.
+func (st *StorageTree) NumLeaves() uint64 {
+	return st.numLeaves
+}
+
+// Add hashes block into a new leaf, appends it to the tree, and
+// rehashes only the leaf's ancestors.
+func (st *StorageTree) Add(block []byte) error {
+	return st.AddBatch([][]byte{block})
+}
+
+// AddBatch hashes blocks into new leaves, appends them to the tree, and
+// rehashes only the new leaves' ancestors — not the whole tree — in one
+// storage batch.
+func (st *StorageTree) AddBatch(blocks [][]byte) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	batch := st.store.NewBatch()
+	pending := make(map[string][]byte)
+	get := func(level int, idx uint64) ([]byte, error) {
+		if v, ok := pending[string(posKey(level, idx))]; ok {
+			return v, nil
+		}
+		return st.getPos(level, idx)
+	}
+	put := func(level int, idx uint64, checksum []byte) error {
+		pending[string(posKey(level, idx))] = checksum
+		return batch.Put(posKey(level, idx), checksum)
+	}
+
+	for _, block := range blocks {
+		checksum := st.checksumFunc(true, block)
+		if err := batch.Put(realLeafKey(st.numLeaves), checksum); err != nil {
+			return err
+		}
+		if err := st.insertAncestors(batch, put, get, st.numLeaves, checksum); err != nil {
+			return err
+		}
+		st.numLeaves++
+	}
+
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+
+	root, err := st.computeRoot()
+	if err != nil {
+		return err
+	}
+	st.root = root
+
+	return nil
+}
+
+// insertAncestors persists leafChecksum as level 0, index leafIdx, then
+// walks up the tree: as long as the node just written completes a real
+// pair with its already-persisted left sibling, it combines them,
+// persists the combined node one level up, and continues: otherwise
+// (the node is the left half of a pair whose right half doesn't exist
+// yet) it stops, leaving that node as the level's solitary, sibling-less
+// tail until a later Add gives it a real pair.
+func (st *StorageTree) insertAncestors(batch Batch, put func(int, uint64, []byte) error, get func(int, uint64) ([]byte, error), leafIdx uint64, leafChecksum []byte) error {
+	level, idx, checksum := 0, leafIdx, leafChecksum
+
+	for {
+		if err := put(level, idx, checksum); err != nil {
+			return err
+		}
+		if level == 0 {
+			if err := batch.Put(checksumKey(checksum), encodeNode(true, nil, nil)); err != nil {
+				return err
+			}
+		}
+
+		if idx%2 == 0 {
+			return nil
+		}
+
+		left, err := get(level, idx-1)
+		if err != nil {
+			return err
+		}
+		combined := st.checksumFunc(false, concat(left, checksum))
+		if err := batch.Put(checksumKey(combined), encodeNode(false, left, checksum)); err != nil {
+			return err
+		}
+
+		level++
+		idx /= 2
+		checksum = combined
+	}
+}
+
+// levelState describes level's real (persisted, permanently stable)
+// node count, plus the checksum of the not-yet-stable node the tree
+// would get by duplicating level's sibling-less tail node with itself,
+// if it has one.
+type levelState struct {
+	realCount uint64
+	virtual   []byte
+}
+
+// levels walks the tree bottom-up and reports, for each level up to and
+// including the root, how many real nodes it has and the checksum its
+// duplicated tail would produce if the tree were finalized right now.
+// Computing this costs one storage fetch per level with a sibling-less
+// tail — O(log numLeaves) total, never a function of numLeaves itself.
+func (st *StorageTree) levels() ([]levelState, error) {
+	if st.numLeaves == 0 {
+		return nil, nil
+	}
+
+	levels := []levelState{{realCount: st.numLeaves}}
+	for {
+		level := len(levels) - 1
+		cur := levels[level]
+
+		if cur.realCount == 1 && cur.virtual == nil && level > 0 {
+			// A single real node with no sibling-less tail beneath it is
+			// already finished: Tree.build stops here too.
+			break
+		}
+
+		var next []byte
+		switch {
+		case cur.realCount%2 == 1:
+			tail, err := st.getPos(level, cur.realCount-1)
+			if err != nil {
+				return nil, err
+			}
+			if cur.virtual == nil {
+				next = st.checksumFunc(false, concat(tail, tail))
+			} else {
+				next = st.checksumFunc(false, concat(tail, cur.virtual))
+			}
+		case cur.virtual != nil:
+			next = st.checksumFunc(false, concat(cur.virtual, cur.virtual))
+		}
+
+		if cur.realCount == 1 {
+			levels = append(levels, levelState{realCount: 0, virtual: next})
+			break
+		}
+		levels = append(levels, levelState{realCount: cur.realCount / 2, virtual: next})
+	}
+
+	return levels, nil
+}
+
+// computeRoot derives the tree's current root from levels, fetching the
+// single real node at the top level if the tree happens to have no
+// sibling-less tail pending anywhere on its rightmost edge.
+func (st *StorageTree) computeRoot() ([]byte, error) {
+	levels, err := st.levels()
+	if err != nil {
+		return nil, err
+	}
+	if levels == nil {
+		return nil, nil
+	}
+
+	top := levels[len(levels)-1]
+	if top.virtual != nil {
+		return top.virtual, nil
+	}
+	return st.getPos(len(levels)-1, 0)
+}
+
+// nodeAt returns the checksum at (level, idx): a real, persisted node if
+// idx falls within level's real count, or the level's duplicated-tail
+// checksum if idx is exactly one past it.
+func nodeAt(st *StorageTree, levels []levelState, level int, idx uint64) ([]byte, error) {
+	if idx < levels[level].realCount {
+		return st.getPos(level, idx)
+	}
+	if idx == levels[level].realCount && levels[level].virtual != nil {
+		return levels[level].virtual, nil
+	}
+	return nil, fmt.Errorf("merkletree: no node at level %d index %d", level, idx)
+}
+
+// GetNode fetches the node persisted under checksum and decodes it,
+// reporting whether it's a leaf and, for a branch, its two children's
+// checksums.
+func (st *StorageTree) GetNode(checksum []byte) (isLeaf bool, left, right []byte, err error) {
+	data, err := st.store.Get(checksumKey(checksum))
+	if err != nil {
+		return false, nil, nil, fmt.Errorf("merkletree: reading node %x: %w", checksum, err)
+	}
+	return decodeNode(data)
+}
+
+// CreateProof builds an audit proof that the leaf at index is included
+// in the tree, fetching only the sibling checksums the proof needs from
+// storage (or deriving them on the fly for a sibling-less tail node that
+// hasn't been persisted as duplicated-with-itself).
+func (st *StorageTree) CreateProof(index uint64) (*Proof, error) {
+	if index >= st.numLeaves {
+		return nil, fmt.Errorf("merkletree: index %d out of range for tree of size %d", index, st.numLeaves)
+	}
+
+	levels, err := st.levels()
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := st.getPos(0, index)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []*ProofPart
+	idx := index
+
+	for level := 0; level < len(levels)-1; level++ {
+		rowLen := levels[level].realCount
+		if levels[level].virtual != nil {
+			rowLen++
+		}
+
+		switch {
+		case idx == rowLen-1 && rowLen%2 == 1:
+			checksum, err := nodeAt(st, levels, level, idx)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, &ProofPart{isRight: true, checksum: checksum})
+		case idx%2 == 0:
+			checksum, err := nodeAt(st, levels, level, idx+1)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, &ProofPart{isRight: true, checksum: checksum})
+		default:
+			checksum, err := nodeAt(st, levels, level, idx-1)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, &ProofPart{isRight: false, checksum: checksum})
+		}
+
+		idx /= 2
+	}
+
+	return &Proof{parts: parts, target: target, checksumFunc: st.checksumFunc}, nil
+}
+
+// VerifyProof reports whether proof reconstructs the tree's root
+// checksum.
+func (st *StorageTree) VerifyProof(proof *Proof) bool {
+	if st.root == nil {
+		return false
+	}
+
+	current := proof.target
+	for _, part := range proof.parts {
+		if part.isRight {
+			current = st.checksumFunc(false, concat(current, part.checksum))
+		} else {
+			current = st.checksumFunc(false, concat(part.checksum, current))
+		}
+	}
+
+	return bytes.Equal(current, st.root)
+}
+
+func (st *StorageTree) getPos(level int, idx uint64) ([]byte, error) {
+	checksum, err := st.store.Get(posKey(level, idx))
+	if err != nil {
+		return nil, fmt.Errorf("merkletree: reading node at level %d index %d: %w", level, idx, err)
+	}
+	return checksum, nil
+}
+
+func realLeafKey(idx uint64) []byte {
+	key := make([]byte, 0, 10)
+	key = append(key, 'r', ':')
+	return binary.BigEndian.AppendUint64(key, idx)
+}
+
+func posKey(level int, idx uint64) []byte {
+	key := make([]byte, 0, 18)
+	key = append(key, 'p', ':')
+	key = binary.BigEndian.AppendUint64(key, uint64(level))
+	return binary.BigEndian.AppendUint64(key, idx)
+}
+
+func checksumKey(checksum []byte) []byte {
+	key := make([]byte, 0, 2+len(checksum))
+	key = append(key, 'n', ':')
+	return append(key, checksum...)
+}
+
+// encodeNode serializes a node record: a single type byte, followed for
+// branches by the length-prefixed left child checksum and the right
+// child checksum.
+func encodeNode(isLeaf bool, left, right []byte) []byte {
+	if isLeaf {
+		return []byte{0}
+	}
+
+	buf := make([]byte, 0, 1+binary.MaxVarintLen64+len(left)+len(right))
+	buf = append(buf, 1)
+	buf = binary.AppendUvarint(buf, uint64(len(left)))
+	buf = append(buf, left...)
+	buf = append(buf, right...)
+	return buf
+}
+
+func decodeNode(data []byte) (isLeaf bool, left, right []byte, err error) {
+	if len(data) == 0 {
+		return false, nil, nil, fmt.Errorf("merkletree: empty node record")
+	}
+	if data[0] == 0 {
+		return true, nil, nil, nil
+	}
+
+	leftLen, n := binary.Uvarint(data[1:])
+	if n <= 0 {
+		return false, nil, nil, fmt.Errorf("merkletree: corrupt node record")
+	}
+
+	rest := data[1+n:]
+	if uint64(len(rest)) < leftLen {
+		return false, nil, nil, fmt.Errorf("merkletree: corrupt node record")
+	}
+
+	return false, rest[:leftLen], rest[leftLen:], nil
+}
@@ -0,0 +1,185 @@
+package merkletree
+
+import (
+	"bytes"
+	"math/bits"
+	"testing"
+)
+
+// testStore is a minimal in-memory Storage used only by this file's
+// tests; the real in-memory adapter lives in storage/memstore and can't
+// be imported here without an import cycle (it imports this package).
+type testStore struct {
+	data map[string][]byte
+	gets int
+}
+
+func newTestStore() *testStore {
+	return &testStore{data: make(map[string][]byte)}
+}
+
+func (s *testStore) Get(key []byte) ([]byte, error) {
+	s.gets++
+	v, ok := s.data[string(key)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return v, nil
+}
+
+func (s *testStore) Put(key, value []byte) error {
+	s.data[string(key)] = append([]byte{}, value...)
+	return nil
+}
+
+func (s *testStore) Delete(key []byte) error {
+	delete(s.data, string(key))
+	return nil
+}
+
+func (s *testStore) NewBatch() Batch {
+	return &testBatch{store: s}
+}
+
+type testBatch struct {
+	store *testStore
+	puts  [][2][]byte
+}
+
+func (b *testBatch) Put(key, value []byte) error {
+	b.puts = append(b.puts, [2][]byte{append([]byte{}, key...), append([]byte{}, value...)})
+	return nil
+}
+
+func (b *testBatch) Delete(key []byte) error {
+	delete(b.store.data, string(key))
+	return nil
+}
+
+func (b *testBatch) Commit() error {
+	for _, kv := range b.puts {
+		b.store.data[string(kv[0])] = kv[1]
+	}
+	return nil
+}
+
+func TestStorageTreeMatchesTree(t *testing.T) {
+	words := []string{"alpha", "beta", "kappa", "gamma", "epsilon", "omega", "mu", "zeta", "eta"}
+
+	for n := 1; n <= len(words); n++ {
+		blocks := make([][]byte, n)
+		for i := 0; i < n; i++ {
+			blocks[i] = []byte(words[i])
+		}
+
+		want := NewTree(Sha256DoubleHash, blocks)
+
+		st := NewTreeWithStorage(Sha256DoubleHash, newTestStore())
+		if err := st.AddBatch(blocks); err != nil {
+			t.Fatalf("n=%d: AddBatch: %v", n, err)
+		}
+
+		if !bytes.Equal(st.Root(), want.root.GetChecksum()) {
+			t.Fatalf("n=%d: got root %x, want %x", n, st.Root(), want.root.GetChecksum())
+		}
+
+		for i, block := range blocks {
+			proof, err := st.CreateProof(uint64(i))
+			if err != nil {
+				t.Fatalf("n=%d i=%d: CreateProof: %v", n, i, err)
+			}
+
+			wantProof, err := want.CreateProof(want.checksumFunc(true, block))
+			if err != nil {
+				t.Fatalf("n=%d i=%d: Tree.CreateProof: %v", n, i, err)
+			}
+
+			if !wantProof.Equals(proof) {
+				t.Fatalf("n=%d i=%d: StorageTree proof differs from Tree proof", n, i)
+			}
+
+			if !st.VerifyProof(proof) {
+				t.Fatalf("n=%d i=%d: StorageTree.VerifyProof = false", n, i)
+			}
+		}
+	}
+}
+
+func TestStorageTreeAddIncrementally(t *testing.T) {
+	blocks := [][]byte{[]byte("alpha"), []byte("beta"), []byte("kappa")}
+
+	st := NewTreeWithStorage(Sha256DoubleHash, newTestStore())
+	for _, block := range blocks {
+		if err := st.Add(block); err != nil {
+			t.Fatalf("Add(%q): %v", block, err)
+		}
+	}
+
+	want := NewTree(Sha256DoubleHash, blocks)
+	if !bytes.Equal(st.Root(), want.root.GetChecksum()) {
+		t.Fatalf("got root %x, want %x", st.Root(), want.root.GetChecksum())
+	}
+}
+
+func TestStorageTreeAddCostIsLogarithmic(t *testing.T) {
+	const n = 1 << 12 // 4096
+
+	store := newTestStore()
+	st := NewTreeWithStorage(Sha256DoubleHash, store)
+
+	for i := 0; i < n; i++ {
+		store.gets = 0
+		if err := st.Add([]byte{byte(i), byte(i >> 8)}); err != nil {
+			t.Fatalf("Add(%d): %v", i, err)
+		}
+		// Add rehashes only the new leaf's ancestors (plus a constant
+		// number of lookups for Root), never the whole existing tree, so
+		// the number of storage reads per call must stay O(log n)
+		// regardless of how many leaves already exist.
+		if max := 2*bits.Len(uint(n)) + 4; store.gets > max {
+			t.Fatalf("Add(%d): %d storage reads, want at most %d", i, store.gets, max)
+		}
+	}
+}
+
+func TestStorageTreeGetNode(t *testing.T) {
+	blocks := [][]byte{[]byte("alpha"), []byte("beta")}
+
+	st := NewTreeWithStorage(Sha256DoubleHash, newTestStore())
+	if err := st.AddBatch(blocks); err != nil {
+		t.Fatalf("AddBatch: %v", err)
+	}
+
+	isLeaf, left, right, err := st.GetNode(st.Root())
+	if err != nil {
+		t.Fatalf("GetNode(root): %v", err)
+	}
+	if isLeaf {
+		t.Fatal("root reported as a leaf")
+	}
+
+	wantLeft := st.checksumFunc(true, []byte("alpha"))
+	wantRight := st.checksumFunc(true, []byte("beta"))
+	if !bytes.Equal(left, wantLeft) || !bytes.Equal(right, wantRight) {
+		t.Fatalf("got children (%x, %x), want (%x, %x)", left, right, wantLeft, wantRight)
+	}
+
+	isLeaf, _, _, err = st.GetNode(wantLeft)
+	if err != nil {
+		t.Fatalf("GetNode(leaf): %v", err)
+	}
+	if !isLeaf {
+		t.Fatal("leaf not reported as a leaf")
+	}
+}
+
+func TestStorageTreeCreateProofOutOfRange(t *testing.T) {
+	st := NewTreeWithStorage(Sha256DoubleHash, newTestStore())
+	if err := st.Add([]byte("alpha")); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	if _, err := st.CreateProof(1); err == nil {
+		t.Fatal("CreateProof: want error for an out-of-range index")
+	}
+}